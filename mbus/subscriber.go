@@ -3,8 +3,8 @@ package mbus
 import (
 	"encoding/json"
 	"errors"
+	"net/url"
 	"os"
-	"strings"
 
 	"code.cloudfoundry.org/routing-api/models"
 
@@ -15,19 +15,25 @@ import (
 
 // RegistryMessage defines the format of a route registration/unregistration
 type RegistryMessage struct {
-	Host                    string            `json:"host"`
-	Port                    uint16            `json:"port"`
-	Uris                    []route.Uri       `json:"uris"`
-	Tags                    map[string]string `json:"tags"`
-	App                     string            `json:"app"`
-	StaleThresholdInSeconds int               `json:"stale_threshold_in_seconds"`
-	RouteServiceURL         string            `json:"route_service_url"`
-	PrivateInstanceID       string            `json:"private_instance_id"`
-	PrivateInstanceIndex    string            `json:"private_instance_index"`
+	Host                    string                 `json:"host"`
+	Port                    uint16                 `json:"port"`
+	Uris                    []route.Uri            `json:"uris"`
+	Tags                    map[string]string      `json:"tags"`
+	App                     string                 `json:"app"`
+	StaleThresholdInSeconds int                    `json:"stale_threshold_in_seconds"`
+	RouteServiceURL         string                 `json:"route_service_url"`
+	PrivateInstanceID       string                 `json:"private_instance_id"`
+	PrivateInstanceIndex    string                 `json:"private_instance_index"`
+	Weight                  int                    `json:"weight"`
+	Attributes              map[string]string      `json:"attributes"`
+	TLS                     bool                   `json:"tls"`
+	ServerCertDomainSAN     string                 `json:"server_cert_domain_san"`
+	PrivateInstancePort     uint16                 `json:"private_instance_port"`
+	ModificationTag         models.ModificationTag `json:"modification_tag"`
 }
 
 func (rm *RegistryMessage) makeEndpoint() *route.Endpoint {
-	return route.NewEndpoint(
+	endpoint := route.NewEndpoint(
 		rm.App,
 		rm.Host,
 		rm.Port,
@@ -36,28 +42,52 @@ func (rm *RegistryMessage) makeEndpoint() *route.Endpoint {
 		rm.Tags,
 		rm.StaleThresholdInSeconds,
 		rm.RouteServiceURL,
-		models.ModificationTag{})
+		rm.ModificationTag)
+
+	if rm.Weight > 0 {
+		endpoint.Weight = rm.Weight
+	}
+	endpoint.Attributes = rm.Attributes
+	endpoint.TLS = rm.TLS
+	endpoint.ServerCertDomainSAN = rm.ServerCertDomainSAN
+	endpoint.PrivateInstancePort = rm.PrivateInstancePort
+
+	return endpoint
 }
 
 // ValidateMessage checks to ensure the registry message is valid
 func (rm *RegistryMessage) ValidateMessage() bool {
-	return rm.RouteServiceURL == "" || strings.HasPrefix(rm.RouteServiceURL, "https")
+	if rm.RouteServiceURL != "" {
+		u, err := url.Parse(rm.RouteServiceURL)
+		if err != nil || u.Scheme != "https" {
+			return false
+		}
+	}
+
+	if rm.TLS && rm.ServerCertDomainSAN == "" {
+		return false
+	}
+
+	return true
 }
 
 // Subscriber subscribes to NATS for all router.* messages and handles them
 type Subscriber struct {
 	natsClient    *nats.Conn
 	routeRegistry registry.Registry
+	reporter      registry.Reporter
 }
 
 // NewSubscriber returns a new Subscriber
 func NewSubscriber(
 	natsClient *nats.Conn,
 	routeRegistry registry.Registry,
+	reporter registry.Reporter,
 ) *Subscriber {
 	return &Subscriber{
 		natsClient:    natsClient,
 		routeRegistry: routeRegistry,
+		reporter:      reporter,
 	}
 }
 
@@ -94,7 +124,7 @@ func (s *Subscriber) subscribeRoutes() error {
 }
 
 func (s *Subscriber) unregisterRoute(message *nats.Msg) {
-	msg, regErr := createRegistryMessage(message.Data)
+	msg, regErr := s.createRegistryMessage("unregister", message.Data)
 	if regErr != nil {
 		return
 	}
@@ -106,7 +136,7 @@ func (s *Subscriber) unregisterRoute(message *nats.Msg) {
 }
 
 func (s *Subscriber) registerRoute(message *nats.Msg) {
-	msg, regErr := createRegistryMessage(message.Data)
+	msg, regErr := s.createRegistryMessage("register", message.Data)
 	if regErr != nil {
 		return
 	}
@@ -117,17 +147,23 @@ func (s *Subscriber) registerRoute(message *nats.Msg) {
 	}
 }
 
-func createRegistryMessage(data []byte) (*RegistryMessage, error) {
+// createRegistryMessage parses and validates a raw NATS message payload,
+// reporting the outcome against op ("register"/"unregister") for the
+// router_registry_messages_total metric.
+func (s *Subscriber) createRegistryMessage(op string, data []byte) (*RegistryMessage, error) {
 	var msg RegistryMessage
 
 	jsonErr := json.Unmarshal(data, &msg)
 	if jsonErr != nil {
+		s.reporter.CaptureRegistryMessage(op, "invalid")
 		return nil, jsonErr
 	}
 
 	if !msg.ValidateMessage() {
+		s.reporter.CaptureRegistryMessage(op, "invalid")
 		return nil, errors.New("Unable to validate message. route_service_url must be https")
 	}
 
+	s.reporter.CaptureRegistryMessage(op, "ok")
 	return &msg, nil
 }
@@ -0,0 +1,61 @@
+package mbus
+
+import (
+	"testing"
+
+	"code.cloudfoundry.org/routing-api/models"
+)
+
+func TestMakeEndpointThreadsModificationTag(t *testing.T) {
+	rm := &RegistryMessage{
+		Host: "10.0.0.1",
+		Port: 8080,
+		ModificationTag: models.ModificationTag{
+			Guid:  "abc",
+			Index: 3,
+		},
+	}
+
+	endpoint := rm.makeEndpoint()
+
+	if endpoint.ModificationTag.Guid != "abc" || endpoint.ModificationTag.Index != 3 {
+		t.Fatalf("expected ModificationTag to be threaded through, got %+v", endpoint.ModificationTag)
+	}
+}
+
+func TestValidateMessageRequiresHttpsRouteServiceUrl(t *testing.T) {
+	cases := []struct {
+		name  string
+		rm    RegistryMessage
+		valid bool
+	}{
+		{"no route service url", RegistryMessage{}, true},
+		{"https route service url", RegistryMessage{RouteServiceURL: "https://rs.example.com"}, true},
+		{"http route service url rejected", RegistryMessage{RouteServiceURL: "http://rs.example.com"}, false},
+		{"unparseable route service url rejected", RegistryMessage{RouteServiceURL: "://bad"}, false},
+	}
+
+	for _, c := range cases {
+		if got := c.rm.ValidateMessage(); got != c.valid {
+			t.Errorf("%s: expected ValidateMessage() = %v, got %v", c.name, c.valid, got)
+		}
+	}
+}
+
+func TestValidateMessageRequiresServerCertDomainSANWhenTLS(t *testing.T) {
+	cases := []struct {
+		name  string
+		rm    RegistryMessage
+		valid bool
+	}{
+		{"TLS without SAN rejected", RegistryMessage{TLS: true}, false},
+		{"TLS with SAN accepted", RegistryMessage{TLS: true, ServerCertDomainSAN: "app.example.com"}, true},
+		{"no TLS, no SAN needed", RegistryMessage{TLS: false}, true},
+	}
+
+	for _, c := range cases {
+		if got := c.rm.ValidateMessage(); got != c.valid {
+			t.Errorf("%s: expected ValidateMessage() = %v, got %v", c.name, c.valid, got)
+		}
+	}
+}
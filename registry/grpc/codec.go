@@ -0,0 +1,37 @@
+package grpc
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// JSONContentSubtype is the gRPC content-subtype clients must opt into
+// (via grpc.CallContentSubtype(JSONContentSubtype)) to talk to
+// RegistryService. This package's message types (RouteFilter,
+// RouteSnapshot, Route, Endpoint) are hand-written stand-ins for what
+// protoc-gen-go would generate and don't implement proto.Message, so
+// grpc-go's built-in "proto" codec would fail every Send/RecvMsg with
+// "message is *grpc.RouteSnapshot, want proto.Message". init registers a
+// plain encoding/json codec under its own content-subtype rather than
+// grpc's default "proto" name, so it only applies to calls that ask for
+// it — other services sharing this process keep using real protobuf.
+// Replace this once registry.proto is run through the real protoc
+// toolchain.
+const JSONContentSubtype = "json"
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string { return JSONContentSubtype }
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,31 @@
+package grpc
+
+import "testing"
+
+func TestJSONCodecRoundTrip(t *testing.T) {
+	codec := jsonCodec{}
+
+	original := &RouteSnapshot{
+		Full:     true,
+		Revision: 7,
+		Routes: []*Route{
+			{Uri: "foo.example.com", Op: Op_REGISTER, Endpoints: []*Endpoint{
+				{Host: "10.0.0.1", Port: 8080},
+			}},
+		},
+	}
+
+	data, err := codec.Marshal(original)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var decoded RouteSnapshot
+	if err := codec.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if decoded.Revision != original.Revision || len(decoded.Routes) != 1 || decoded.Routes[0].Uri != "foo.example.com" {
+		t.Fatalf("round trip mismatch: got %+v", decoded)
+	}
+}
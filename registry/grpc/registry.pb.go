@@ -0,0 +1,41 @@
+// Hand-written stand-ins for the message types protoc-gen-go would
+// generate from registry.proto. They intentionally do not implement
+// proto.Message; see codec.go, which registers a plain JSON codec under
+// the "json" content-subtype so WatchRoutes works without the real
+// protobuf toolchain. Replace this file by running protoc-gen-go against
+// registry.proto once that toolchain is available, and delete codec.go.
+
+package grpc
+
+type Op int32
+
+const (
+	Op_REGISTER   Op = 0
+	Op_UNREGISTER Op = 1
+)
+
+type RouteFilter struct {
+	UriPrefix     string
+	SinceRevision int64
+}
+
+type RouteSnapshot struct {
+	Full     bool
+	Revision int64
+	Routes   []*Route
+}
+
+type Route struct {
+	Uri       string
+	Endpoints []*Endpoint
+	Op        Op
+}
+
+type Endpoint struct {
+	ApplicationId     string
+	Host              string
+	Port              uint32
+	PrivateInstanceId string
+	Weight            int32
+	Attributes        map[string]string
+}
@@ -0,0 +1,59 @@
+// Hand-written stand-in for the gRPC service plumbing protoc-gen-go-grpc
+// would generate from registry.proto. See registry.pb.go and codec.go for
+// why: these message types don't implement proto.Message, so callers must
+// dial/call with grpc.CallContentSubtype(JSONContentSubtype) to pick up
+// the JSON codec codec.go registers. Replace this file by running
+// protoc-gen-go-grpc against registry.proto once that toolchain is
+// available.
+
+package grpc
+
+import (
+	"google.golang.org/grpc"
+)
+
+// RegistryServiceServer is the server API for RegistryService.
+type RegistryServiceServer interface {
+	WatchRoutes(*RouteFilter, RegistryService_WatchRoutesServer) error
+}
+
+// RegistryService_WatchRoutesServer is the server-side stream handle for
+// the WatchRoutes RPC.
+type RegistryService_WatchRoutesServer interface {
+	Send(*RouteSnapshot) error
+	grpc.ServerStream
+}
+
+// RegisterRegistryServiceServer registers srv as the implementation of the
+// RegistryService with s.
+func RegisterRegistryServiceServer(s *grpc.Server, srv RegistryServiceServer) {
+	s.RegisterService(&_RegistryService_serviceDesc, srv)
+}
+
+var _RegistryService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "registry.RegistryService",
+	HandlerType: (*RegistryServiceServer)(nil),
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchRoutes",
+			Handler:       _RegistryService_WatchRoutes_Handler,
+			ServerStreams: true,
+		},
+	},
+}
+
+func _RegistryService_WatchRoutes_Handler(srv interface{}, stream grpc.ServerStream) error {
+	filter := new(RouteFilter)
+	if err := stream.RecvMsg(filter); err != nil {
+		return err
+	}
+	return srv.(RegistryServiceServer).WatchRoutes(filter, &registryServiceWatchRoutesServer{stream})
+}
+
+type registryServiceWatchRoutesServer struct {
+	grpc.ServerStream
+}
+
+func (s *registryServiceWatchRoutesServer) Send(snapshot *RouteSnapshot) error {
+	return s.ServerStream.SendMsg(snapshot)
+}
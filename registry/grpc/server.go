@@ -0,0 +1,100 @@
+// Package grpc exposes registry.RouteRegistry to external dataplanes over a
+// gRPC streaming API (see registry.proto), as an alternative to polling
+// MarshalJSON or subscribing to NATS.
+package grpc
+
+import (
+	"strings"
+
+	"github.com/df010/router-lite/registry"
+	"github.com/df010/router-lite/route"
+)
+
+const subscriberBufferSize = 1024
+
+// Server implements RegistryServiceServer on top of a RouteRegistry.
+type Server struct {
+	routeRegistry *registry.RouteRegistry
+}
+
+// NewServer returns a Server backed by routeRegistry.
+func NewServer(routeRegistry *registry.RouteRegistry) *Server {
+	return &Server{routeRegistry: routeRegistry}
+}
+
+// WatchRoutes sends the current route snapshot, filtered by
+// filter.UriPrefix, then streams incremental Register/Unregister deltas as
+// they occur. A SinceRevision the server's per-subscriber buffer can no
+// longer satisfy is treated the same as SinceRevision == 0: the client gets
+// a fresh full snapshot before the incremental stream resumes.
+func (s *Server) WatchRoutes(filter *RouteFilter, stream RegistryService_WatchRoutesServer) error {
+	subID, events, startRevision := s.routeRegistry.Subscribe(subscriberBufferSize)
+	defer s.routeRegistry.Unsubscribe(subID)
+
+	if filter.SinceRevision == 0 || filter.SinceRevision != startRevision {
+		if err := stream.Send(s.snapshot(filter.UriPrefix, startRevision)); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if !strings.HasPrefix(string(event.Uri), filter.UriPrefix) {
+				continue
+			}
+			if err := stream.Send(deltaSnapshot(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *Server) snapshot(uriPrefix string, revision int64) *RouteSnapshot {
+	routes := []*Route{}
+	for uri, endpoints := range s.routeRegistry.ToMap() {
+		if !strings.HasPrefix(uri, uriPrefix) {
+			continue
+		}
+		routes = append(routes, &Route{Uri: uri, Endpoints: toPbEndpoints(endpoints)})
+	}
+
+	return &RouteSnapshot{Full: true, Revision: revision, Routes: routes}
+}
+
+func deltaSnapshot(event registry.ChangeEvent) *RouteSnapshot {
+	op := Op_REGISTER
+	if event.Op == registry.OpUnregister {
+		op = Op_UNREGISTER
+	}
+
+	return &RouteSnapshot{
+		Full:     false,
+		Revision: event.Revision,
+		Routes: []*Route{{
+			Uri:       string(event.Uri),
+			Endpoints: toPbEndpoints([]*route.Endpoint{event.Endpoint}),
+			Op:        op,
+		}},
+	}
+}
+
+func toPbEndpoints(endpoints []*route.Endpoint) []*Endpoint {
+	pbEndpoints := make([]*Endpoint, len(endpoints))
+	for i, e := range endpoints {
+		pbEndpoints[i] = &Endpoint{
+			ApplicationId:     e.ApplicationId,
+			Host:              e.Host,
+			Port:              uint32(e.Port),
+			PrivateInstanceId: e.PrivateInstanceId,
+			Weight:            int32(e.Weight),
+			Attributes:        e.Attributes,
+		}
+	}
+	return pbEndpoints
+}
@@ -0,0 +1,106 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"code.cloudfoundry.org/routing-api/models"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/df010/router-lite/registry"
+	"github.com/df010/router-lite/route"
+)
+
+// fakeWatchRoutesStream implements RegistryService_WatchRoutesServer without
+// a real gRPC connection, so WatchRoutes can be driven directly in tests.
+type fakeWatchRoutesStream struct {
+	ctx context.Context
+	out chan *RouteSnapshot
+}
+
+func newFakeWatchRoutesStream(ctx context.Context) *fakeWatchRoutesStream {
+	return &fakeWatchRoutesStream{ctx: ctx, out: make(chan *RouteSnapshot, 8)}
+}
+
+func (f *fakeWatchRoutesStream) Send(snapshot *RouteSnapshot) error {
+	f.out <- snapshot
+	return nil
+}
+
+func (f *fakeWatchRoutesStream) Context() context.Context     { return f.ctx }
+func (f *fakeWatchRoutesStream) SetHeader(metadata.MD) error  { return nil }
+func (f *fakeWatchRoutesStream) SendHeader(metadata.MD) error { return nil }
+func (f *fakeWatchRoutesStream) SetTrailer(metadata.MD)       {}
+func (f *fakeWatchRoutesStream) SendMsg(m interface{}) error  { return nil }
+func (f *fakeWatchRoutesStream) RecvMsg(m interface{}) error  { return nil }
+
+func TestWatchRoutesSendsFullSnapshotThenDeltas(t *testing.T) {
+	routeRegistry := registry.NewRouteRegistry()
+	routeRegistry.Register("foo.example.com", route.NewEndpoint("app", "10.0.0.1", 8080, "instance", "0", nil, 0, "", models.ModificationTag{}))
+
+	server := NewServer(routeRegistry)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := newFakeWatchRoutesStream(ctx)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- server.WatchRoutes(&RouteFilter{UriPrefix: "foo."}, stream)
+	}()
+
+	snapshot := <-stream.out
+	if !snapshot.Full || len(snapshot.Routes) != 1 || snapshot.Routes[0].Uri != "foo.example.com" {
+		t.Fatalf("expected an initial full snapshot containing foo.example.com, got %+v", snapshot)
+	}
+
+	routeRegistry.Register("foo.example.com", route.NewEndpoint("app", "10.0.0.2", 8080, "instance-2", "0", nil, 0, "", models.ModificationTag{}))
+
+	delta := <-stream.out
+	if delta.Full {
+		t.Fatalf("expected an incremental (non-full) delta after the snapshot, got %+v", delta)
+	}
+	if len(delta.Routes) != 1 || delta.Routes[0].Op != Op_REGISTER {
+		t.Fatalf("expected a single REGISTER delta, got %+v", delta)
+	}
+
+	routeRegistry.Register("bar.example.com", route.NewEndpoint("app", "10.0.0.3", 8080, "instance-3", "0", nil, 0, "", models.ModificationTag{}))
+
+	select {
+	case got := <-stream.out:
+		t.Fatalf("expected bar.example.com to be filtered out by UriPrefix, got %+v", got)
+	default:
+	}
+
+	cancel()
+	if err := <-done; err != ctx.Err() {
+		t.Fatalf("expected WatchRoutes to return the context's error after cancellation, got %v", err)
+	}
+}
+
+func TestWatchRoutesTreatsCursorMissLikeFreshSubscribe(t *testing.T) {
+	routeRegistry := registry.NewRouteRegistry()
+	routeRegistry.Register("foo.example.com", route.NewEndpoint("app", "10.0.0.1", 8080, "instance", "0", nil, 0, "", models.ModificationTag{}))
+
+	server := NewServer(routeRegistry)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := newFakeWatchRoutesStream(ctx)
+
+	done := make(chan error, 1)
+	// A SinceRevision that doesn't match the subscription's own starting
+	// revision (e.g. the client asked to resume somewhere the server's
+	// per-subscriber buffer can no longer satisfy) must fall back to a
+	// full snapshot instead of silently resuming mid-stream.
+	go func() {
+		done <- server.WatchRoutes(&RouteFilter{UriPrefix: "", SinceRevision: 999}, stream)
+	}()
+
+	snapshot := <-stream.out
+	if !snapshot.Full {
+		t.Fatalf("expected a cursor-miss SinceRevision to produce a full snapshot, got %+v", snapshot)
+	}
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,33 @@
+package registry
+
+import (
+	"os"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// Logger is the structured logging interface RouteRegistry emits its
+// lifecycle events (endpoint-registered, endpoint-pruned,
+// pruning-suspended, pruning-resumed, ...) through. It is satisfied by
+// hclog.Logger directly.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// NewLogger returns an hclog-backed Logger named name, logging to stderr.
+func NewLogger(name string) Logger {
+	return hclog.New(&hclog.LoggerOptions{
+		Name:   name,
+		Output: os.Stderr,
+	})
+}
+
+// nopLogger is the default Logger a RouteRegistry starts with, so callers
+// that never call SetLogger don't have to nil-check.
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
@@ -0,0 +1,19 @@
+package registry
+
+import "testing"
+
+func TestNewLoggerImplementsLogger(t *testing.T) {
+	var logger Logger = NewLogger("test")
+
+	// NewLogger must return a working Logger; these just need to not panic.
+	logger.Debug("debug-message", "k", "v")
+	logger.Info("info-message", "k", "v")
+	logger.Error("error-message", "k", "v")
+}
+
+func TestNopLoggerDoesNotPanic(t *testing.T) {
+	var logger Logger = nopLogger{}
+	logger.Debug("debug-message")
+	logger.Info("info-message")
+	logger.Error("error-message")
+}
@@ -52,15 +52,125 @@ type RouteRegistry struct {
 
 	ticker           *time.Ticker
 	timeOfLastUpdate time.Time
+
+	logger   Logger
+	reporter Reporter
+
+	// staleUpdatesRejected counts Register/Unregister calls dropped because
+	// the incoming endpoint's ModificationTag didn't succeed the one
+	// already stored, e.g. an out-of-order NATS message replaying a stale
+	// Register or Unregister.
+	staleUpdatesRejected int64
+
+	// revision and subscribers back ChangeEvent streaming for
+	// registry/grpc: every accepted Register, and every Unregister that
+	// actually removes an endpoint, bumps revision and fans the resulting
+	// event out to each subscriber's own buffered channel. Rejected stale
+	// updates and no-op Unregisters leave revision unchanged.
+	revision    int64
+	subscribers map[uint64]*changeSubscription
+	nextSubID   uint64
 }
 
 func NewRouteRegistry() *RouteRegistry {
 	r := &RouteRegistry{}
 	r.byUri = container.NewTrie()
 	r.suspendPruning = func() bool { return false }
+	r.subscribers = make(map[uint64]*changeSubscription)
+	r.logger = nopLogger{}
+	r.reporter = nopReporter{}
 	return r
 }
 
+// SetLogger overrides the registry's Logger. The default, installed by
+// NewRouteRegistry, discards everything.
+func (r *RouteRegistry) SetLogger(logger Logger) {
+	r.Lock()
+	r.logger = logger
+	r.Unlock()
+}
+
+// SetReporter overrides the registry's Reporter. The default, installed by
+// NewRouteRegistry, discards everything.
+func (r *RouteRegistry) SetReporter(reporter Reporter) {
+	r.Lock()
+	r.reporter = reporter
+	r.Unlock()
+}
+
+// ChangeOp identifies which registry operation produced a ChangeEvent.
+type ChangeOp int
+
+const (
+	OpRegister ChangeOp = iota
+	OpUnregister
+)
+
+// ChangeEvent is a single Register/Unregister delta, tagged with the
+// monotonic revision it produced, for registry/grpc's WatchRoutes stream.
+type ChangeEvent struct {
+	Revision int64
+	Op       ChangeOp
+	Uri      route.Uri
+	Endpoint *route.Endpoint
+}
+
+// changeSubscription is a subscriber's own ring buffer of pending
+// ChangeEvents. A full buffer drops the event rather than blocking
+// Register/Unregister; the subscriber detects the gap via since_revision
+// on its next reconnect and falls back to a full snapshot.
+type changeSubscription struct {
+	events chan ChangeEvent
+}
+
+// Subscribe registers interest in future ChangeEvents and returns the
+// subscription id (for Unsubscribe) alongside the event channel and the
+// revision the subscription starts at. bufferSize sizes the subscriber's
+// ring buffer.
+func (r *RouteRegistry) Subscribe(bufferSize int) (id uint64, events <-chan ChangeEvent, startRevision int64) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.nextSubID++
+	id = r.nextSubID
+	sub := &changeSubscription{events: make(chan ChangeEvent, bufferSize)}
+	r.subscribers[id] = sub
+
+	return id, sub.events, r.revision
+}
+
+// Unsubscribe stops and discards a subscription created by Subscribe.
+func (r *RouteRegistry) Unsubscribe(id uint64) {
+	r.Lock()
+	defer r.Unlock()
+
+	if sub, ok := r.subscribers[id]; ok {
+		delete(r.subscribers, id)
+		close(sub.events)
+	}
+}
+
+// Revision returns the current monotonic change revision.
+func (r *RouteRegistry) Revision() int64 {
+	r.RLock()
+	defer r.RUnlock()
+	return r.revision
+}
+
+// publish bumps the revision and fans the resulting ChangeEvent out to
+// every subscriber. Callers must hold the write lock.
+func (r *RouteRegistry) publish(op ChangeOp, uri route.Uri, endpoint *route.Endpoint) {
+	r.revision++
+	event := ChangeEvent{Revision: r.revision, Op: op, Uri: uri, Endpoint: endpoint}
+
+	for _, sub := range r.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+		}
+	}
+}
+
 func (r *RouteRegistry) Register(uri route.Uri, endpoint *route.Endpoint) {
 	t := time.Now()
 
@@ -75,24 +185,33 @@ func (r *RouteRegistry) Register(uri route.Uri, endpoint *route.Endpoint) {
 		r.byUri.Insert(routekey, pool)
 	}
 
-	pool.Put(endpoint)
+	accepted, endpointAdded := pool.Put(endpoint)
+	if !accepted {
+		r.staleUpdatesRejected++
+		r.Unlock()
+		r.logger.Debug("endpoint-stale-update-rejected", "uri", routekey, "backend", endpoint.CanonicalAddr(), "modification_tag", endpoint.ModificationTag)
+		return
+	}
 
 	r.timeOfLastUpdate = t
+	r.publish(OpRegister, routekey, endpoint)
+	r.reporter.CaptureRouteStats(r.byUri.PoolCount(), r.byUri.EndpointCount())
 	r.Unlock()
 
-	// r.reporter.CaptureRegistryMessage(endpoint)
-	//
-	// zapData := []zap.Field{
-	// 	zap.Stringer("uri", uri),
-	// 	zap.String("backend", endpoint.CanonicalAddr()),
-	// 	zap.Object("modification_tag", endpoint.ModificationTag),
-	// }
-	//
-	// if endpointAdded {
-	// 	r.logger.Debug("endpoint-registered", zapData...)
-	// } else {
-	// 	r.logger.Debug("endpoint-not-registered", zapData...)
-	// }
+	if endpointAdded {
+		r.logger.Debug("endpoint-registered", "uri", routekey, "backend", endpoint.CanonicalAddr(), "modification_tag", endpoint.ModificationTag)
+	} else {
+		r.logger.Debug("endpoint-not-registered", "uri", routekey, "backend", endpoint.CanonicalAddr(), "modification_tag", endpoint.ModificationTag)
+	}
+}
+
+// StaleUpdatesRejected returns the number of Register/Unregister calls
+// dropped so far because their ModificationTag did not succeed the stored
+// endpoint's.
+func (r *RouteRegistry) StaleUpdatesRejected() int64 {
+	r.RLock()
+	defer r.RUnlock()
+	return r.staleUpdatesRejected
 }
 
 func (r *RouteRegistry) Unregister(uri route.Uri, endpoint *route.Endpoint) {
@@ -101,11 +220,24 @@ func (r *RouteRegistry) Unregister(uri route.Uri, endpoint *route.Endpoint) {
 	uri = uri.RouteKey()
 
 	pool := r.byUri.Find(uri)
-	if pool != nil {
-		pool.Remove(endpoint)
-		if pool.IsEmpty() {
-			r.byUri.Delete(uri)
-		}
+	if pool == nil {
+		r.Unlock()
+		return
+	}
+
+	accepted, removed := pool.Remove(endpoint)
+	if !accepted {
+		r.staleUpdatesRejected++
+		r.Unlock()
+		r.logger.Debug("endpoint-stale-unregister-rejected", "uri", uri, "backend", endpoint.CanonicalAddr(), "modification_tag", endpoint.ModificationTag)
+		return
+	}
+
+	if pool.IsEmpty() {
+		r.byUri.Delete(uri)
+	}
+	if removed {
+		r.publish(OpUnregister, uri, endpoint)
 	}
 
 	r.Unlock()
@@ -126,6 +258,28 @@ func (r *RouteRegistry) Lookup(uri route.Uri) *route.Pool {
 	return pool
 }
 
+// LookupWithAffinity behaves like Lookup, but returns the pool's endpoints
+// in a weighted-random order biased by each endpoint's static Weight and
+// its affinity score against prefs (e.g. prefer same az/rack).
+func (r *RouteRegistry) LookupWithAffinity(uri route.Uri, prefs []route.AffinityPref) *route.Pool {
+	pool := r.Lookup(uri)
+	if pool == nil {
+		return nil
+	}
+	return pool.WeightedSample(prefs)
+}
+
+// LookupTLS behaves like Lookup, but filters the pool down to endpoints
+// that can be dialed directly over TLS, so a downstream proxy can choose
+// the right dial path per request.
+func (r *RouteRegistry) LookupTLS(uri route.Uri) *route.Pool {
+	pool := r.Lookup(uri)
+	if pool == nil {
+		return nil
+	}
+	return pool.FilterTLS()
+}
+
 func (r *RouteRegistry) LookupWithInstance(uri route.Uri, appId string, appIndex string) *route.Pool {
 	uri = uri.RouteKey()
 	p := r.Lookup(uri)
@@ -197,12 +351,25 @@ func (r *RouteRegistry) MarshalJSON() ([]byte, error) {
 	return json.Marshal(r.byUri.ToMap())
 }
 
+// ToMap returns the same uri -> endpoints snapshot MarshalJSON serializes,
+// for callers that want the Go value directly (e.g. registry/grpc's
+// initial WatchRoutes snapshot).
+func (r *RouteRegistry) ToMap() map[string][]*route.Endpoint {
+	r.RLock()
+	defer r.RUnlock()
+
+	return r.byUri.ToMap()
+}
+
 func (r *RouteRegistry) pruneStaleDroplets() {
 	r.Lock()
 	defer r.Unlock()
 
 	// suspend pruning if option enabled and if NATS is unavailable
 	if r.suspendPruning() {
+		if r.pruningStatus != DISCONNECTED {
+			r.logger.Info("pruning-suspended")
+		}
 		r.pruningStatus = DISCONNECTED
 		return
 	} else {
@@ -210,20 +377,36 @@ func (r *RouteRegistry) pruneStaleDroplets() {
 			// if we are coming back from being disconnected from source,
 			// bulk update routes / mark updated to avoid pruning right away
 			r.freshenRoutes()
+			r.logger.Info("pruning-resumed")
 		}
 		r.pruningStatus = CONNECTED
 	}
 
+	var snipped int
 	r.byUri.EachNodeWithPool(func(t *container.Trie) {
 		endpoints := t.Pool.PruneEndpoints(r.dropletStaleThreshold)
+		wasEmpty := t.Pool.IsEmpty()
 		t.Snip()
+		if wasEmpty {
+			snipped++
+		}
+
 		if len(endpoints) > 0 {
 			addresses := []string{}
 			for _, e := range endpoints {
 				addresses = append(addresses, e.CanonicalAddr())
 			}
+			r.logger.Debug("endpoint-pruned", "addresses", addresses)
+			r.reporter.CaptureEndpointsPruned("stale", len(endpoints))
 		}
 	})
+
+	if snipped > 0 {
+		r.reporter.CaptureEndpointsPruned("snip", snipped)
+	}
+
+	r.reporter.CaptureRouteStats(r.byUri.PoolCount(), r.byUri.EndpointCount())
+	r.reporter.CaptureMsSinceLastUpdate(float64(time.Since(r.timeOfLastUpdate).Milliseconds()))
 }
 
 func (r *RouteRegistry) SuspendPruning(f func() bool) {
@@ -0,0 +1,104 @@
+package registry
+
+import (
+	"testing"
+
+	"code.cloudfoundry.org/routing-api/models"
+
+	"github.com/df010/router-lite/route"
+)
+
+func TestUnregisterDoesNotPublishOnNoOp(t *testing.T) {
+	r := NewRouteRegistry()
+
+	endpoint := route.NewEndpoint("app", "10.0.0.1", 8080, "instance", "0", nil, 0, "", models.ModificationTag{})
+	r.Register("foo.example.com", endpoint)
+
+	_, events, startRevision := r.Subscribe(8)
+	revisionAfterRegister := r.Revision()
+	if revisionAfterRegister != startRevision {
+		t.Fatalf("expected no change between subscribe and first assertion, got %d -> %d", startRevision, revisionAfterRegister)
+	}
+
+	// Unregistering an endpoint that was never registered against this uri
+	// must be a no-op: no revision bump, no event delivered.
+	other := route.NewEndpoint("app", "10.0.0.2", 8080, "other-instance", "0", nil, 0, "", models.ModificationTag{})
+	r.Unregister("foo.example.com", other)
+
+	if r.Revision() != revisionAfterRegister {
+		t.Fatalf("expected revision to stay at %d after a no-op Unregister, got %d", revisionAfterRegister, r.Revision())
+	}
+
+	select {
+	case ev := <-events:
+		t.Fatalf("expected no event from a no-op Unregister, got %+v", ev)
+	default:
+	}
+}
+
+func TestUnregisterRejectsStaleModificationTag(t *testing.T) {
+	r := NewRouteRegistry()
+
+	current := route.NewEndpoint("app", "10.0.0.1", 8080, "instance", "0", nil, 0, "", models.ModificationTag{Guid: "a", Index: 2})
+	r.Register("foo.example.com", current)
+
+	_, events, _ := r.Subscribe(8)
+	revisionBeforeUnregister := r.Revision()
+
+	// An Unregister carrying an older tag than what's stored must be
+	// rejected, the same way a stale Register is, so an out-of-order
+	// Unregister can't remove an endpoint a newer Register already
+	// superseded.
+	stale := route.NewEndpoint("app", "10.0.0.1", 8080, "instance", "0", nil, 0, "", models.ModificationTag{Guid: "a", Index: 1})
+	r.Unregister("foo.example.com", stale)
+
+	if r.Revision() != revisionBeforeUnregister {
+		t.Fatalf("expected revision to stay at %d after a rejected stale Unregister, got %d", revisionBeforeUnregister, r.Revision())
+	}
+	if got := r.StaleUpdatesRejected(); got != 1 {
+		t.Fatalf("expected StaleUpdatesRejected to report 1, got %d", got)
+	}
+
+	select {
+	case ev := <-events:
+		t.Fatalf("expected no event from a rejected stale Unregister, got %+v", ev)
+	default:
+	}
+
+	pool := r.Lookup("foo.example.com")
+	if pool == nil || pool.IsEmpty() {
+		t.Fatalf("expected the endpoint to remain registered after the stale Unregister was rejected")
+	}
+}
+
+func TestLookupTLSFiltersToTLSCapableEndpoints(t *testing.T) {
+	r := NewRouteRegistry()
+
+	plain := route.NewEndpoint("app", "10.0.0.1", 8080, "plain-instance", "0", nil, 0, "", models.ModificationTag{})
+	tlsEndpoint := route.NewEndpoint("app", "10.0.0.2", 8443, "tls-instance", "0", nil, 0, "", models.ModificationTag{})
+	tlsEndpoint.TLS = true
+	tlsEndpoint.ServerCertDomainSAN = "app.example.com"
+
+	r.Register("foo.example.com", plain)
+	r.Register("foo.example.com", tlsEndpoint)
+
+	pool := r.LookupTLS("foo.example.com")
+	if pool == nil {
+		t.Fatalf("expected a non-nil pool from LookupTLS")
+	}
+
+	var seen []*route.Endpoint
+	pool.Each(func(e *route.Endpoint) { seen = append(seen, e) })
+
+	if len(seen) != 1 || seen[0].PrivateInstanceId != "tls-instance" {
+		t.Fatalf("expected LookupTLS to return only the TLS-capable endpoint, got %+v", seen)
+	}
+}
+
+func TestLookupTLSOnUnknownUriReturnsNil(t *testing.T) {
+	r := NewRouteRegistry()
+
+	if pool := r.LookupTLS("missing.example.com"); pool != nil {
+		t.Fatalf("expected LookupTLS on an unregistered uri to return nil, got %+v", pool)
+	}
+}
@@ -0,0 +1,98 @@
+package registry
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Reporter receives RouteRegistry's operational metrics. The default,
+// Prometheus-backed implementation is PrometheusReporter; callers that
+// don't want metrics can leave a RouteRegistry's reporter unset.
+type Reporter interface {
+	// CaptureRouteStats reports the current route/endpoint table size.
+	CaptureRouteStats(totalRoutes, totalEndpoints int)
+	// CaptureMsSinceLastUpdate reports how long it's been since the
+	// registry last accepted a Register; Unregister does not reset it.
+	CaptureMsSinceLastUpdate(ms float64)
+	// CaptureEndpointsPruned reports what the pruning cycle removed, tagged
+	// by reason: for "stale", count is the number of stale endpoints
+	// removed; for "snip", it is the number of emptied routes snipped from
+	// the table.
+	CaptureEndpointsPruned(reason string, count int)
+	// CaptureRegistryMessage reports an incoming mbus registry message,
+	// tagged by op ("register"/"unregister") and result ("ok"/"invalid").
+	CaptureRegistryMessage(op, result string)
+}
+
+// nopReporter is the default Reporter a RouteRegistry starts with, so
+// callers that never call SetReporter don't have to nil-check.
+type nopReporter struct{}
+
+func (nopReporter) CaptureRouteStats(int, int)            {}
+func (nopReporter) CaptureMsSinceLastUpdate(float64)      {}
+func (nopReporter) CaptureEndpointsPruned(string, int)    {}
+func (nopReporter) CaptureRegistryMessage(string, string) {}
+
+// PrometheusReporter is the default production Reporter, exporting
+// router_total_routes, router_total_endpoints, router_ms_since_last_update,
+// router_pruned_endpoints_total, and router_registry_messages_total.
+type PrometheusReporter struct {
+	totalRoutes           prometheus.Gauge
+	totalEndpoints        prometheus.Gauge
+	msSinceLastUpdate     prometheus.Gauge
+	prunedEndpointsTotal  *prometheus.CounterVec
+	registryMessagesTotal *prometheus.CounterVec
+}
+
+// NewPrometheusReporter builds a PrometheusReporter and registers its
+// collectors against reg.
+func NewPrometheusReporter(reg prometheus.Registerer) *PrometheusReporter {
+	r := &PrometheusReporter{
+		totalRoutes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "router_total_routes",
+			Help: "Number of distinct routes currently held by the registry.",
+		}),
+		totalEndpoints: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "router_total_endpoints",
+			Help: "Number of endpoints currently held by the registry, across all routes.",
+		}),
+		msSinceLastUpdate: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "router_ms_since_last_update",
+			Help: "Milliseconds since the registry last accepted a Register.",
+		}),
+		prunedEndpointsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "router_pruned_endpoints_total",
+			Help: "Removed by the pruning cycle, by reason: stale endpoints (stale) or emptied routes (snip).",
+		}, []string{"reason"}),
+		registryMessagesTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "router_registry_messages_total",
+			Help: "Registry messages processed, by operation and result.",
+		}, []string{"op", "result"}),
+	}
+
+	reg.MustRegister(
+		r.totalRoutes,
+		r.totalEndpoints,
+		r.msSinceLastUpdate,
+		r.prunedEndpointsTotal,
+		r.registryMessagesTotal,
+	)
+
+	return r
+}
+
+func (r *PrometheusReporter) CaptureRouteStats(totalRoutes, totalEndpoints int) {
+	r.totalRoutes.Set(float64(totalRoutes))
+	r.totalEndpoints.Set(float64(totalEndpoints))
+}
+
+func (r *PrometheusReporter) CaptureMsSinceLastUpdate(ms float64) {
+	r.msSinceLastUpdate.Set(ms)
+}
+
+func (r *PrometheusReporter) CaptureEndpointsPruned(reason string, count int) {
+	r.prunedEndpointsTotal.WithLabelValues(reason).Add(float64(count))
+}
+
+func (r *PrometheusReporter) CaptureRegistryMessage(op, result string) {
+	r.registryMessagesTotal.WithLabelValues(op, result).Inc()
+}
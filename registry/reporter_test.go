@@ -0,0 +1,50 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestPrometheusReporterCapturesMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	reporter := NewPrometheusReporter(reg)
+
+	reporter.CaptureRouteStats(3, 7)
+	reporter.CaptureMsSinceLastUpdate(42.5)
+	reporter.CaptureEndpointsPruned("stale", 2)
+	reporter.CaptureEndpointsPruned("snip", 1)
+	reporter.CaptureRegistryMessage("register", "ok")
+	reporter.CaptureRegistryMessage("register", "invalid")
+
+	if got := testutil.ToFloat64(reporter.totalRoutes); got != 3 {
+		t.Fatalf("expected router_total_routes to be 3, got %v", got)
+	}
+	if got := testutil.ToFloat64(reporter.totalEndpoints); got != 7 {
+		t.Fatalf("expected router_total_endpoints to be 7, got %v", got)
+	}
+	if got := testutil.ToFloat64(reporter.msSinceLastUpdate); got != 42.5 {
+		t.Fatalf("expected router_ms_since_last_update to be 42.5, got %v", got)
+	}
+	if got := testutil.ToFloat64(reporter.prunedEndpointsTotal.WithLabelValues("stale")); got != 2 {
+		t.Fatalf("expected router_pruned_endpoints_total{reason=stale} to be 2, got %v", got)
+	}
+	if got := testutil.ToFloat64(reporter.prunedEndpointsTotal.WithLabelValues("snip")); got != 1 {
+		t.Fatalf("expected router_pruned_endpoints_total{reason=snip} to be 1, got %v", got)
+	}
+	if got := testutil.ToFloat64(reporter.registryMessagesTotal.WithLabelValues("register", "ok")); got != 1 {
+		t.Fatalf("expected router_registry_messages_total{op=register,result=ok} to be 1, got %v", got)
+	}
+	if got := testutil.ToFloat64(reporter.registryMessagesTotal.WithLabelValues("register", "invalid")); got != 1 {
+		t.Fatalf("expected router_registry_messages_total{op=register,result=invalid} to be 1, got %v", got)
+	}
+}
+
+func TestNopReporterDoesNotPanic(t *testing.T) {
+	var r Reporter = nopReporter{}
+	r.CaptureRouteStats(1, 1)
+	r.CaptureMsSinceLastUpdate(1)
+	r.CaptureEndpointsPruned("stale", 1)
+	r.CaptureRegistryMessage("register", "ok")
+}
@@ -0,0 +1,243 @@
+package source
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/routing-api/models"
+	consulapi "github.com/hashicorp/consul/api"
+
+	"github.com/df010/router-lite/registry"
+	"github.com/df010/router-lite/route"
+)
+
+// consulErrorBackoffMin/Max bound the delay between retries of a failing
+// blocking query, so an unreachable Consul agent doesn't get hammered in a
+// tight loop.
+const (
+	consulErrorBackoffMin = 500 * time.Millisecond
+	consulErrorBackoffMax = 30 * time.Second
+)
+
+// ConsulSource watches a Consul service (or KV) prefix using blocking
+// queries and registers/unregisters endpoints as the catalog changes.
+type ConsulSource struct {
+	client        *consulapi.Client
+	routeRegistry registry.Registry
+
+	// ServicePrefix is the Consul service name to watch. KVPrefix, if set,
+	// is watched instead of the catalog and expects a JSON-encoded
+	// consulKVEntry under each key.
+	ServicePrefix string
+	KVPrefix      string
+
+	mu        sync.Mutex
+	healthErr error
+}
+
+// NewConsulSource returns a ConsulSource backed by the given Consul client.
+func NewConsulSource(client *consulapi.Client, routeRegistry registry.Registry, servicePrefix string) *ConsulSource {
+	return &ConsulSource{
+		client:        client,
+		routeRegistry: routeRegistry,
+		ServicePrefix: servicePrefix,
+	}
+}
+
+// Run manages the lifecycle of the Consul watch loop. If KVPrefix is set,
+// it takes priority and the KV tree is watched instead of the service
+// catalog.
+func (c *ConsulSource) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	stop := make(chan struct{})
+	go func() {
+		<-signals
+		close(stop)
+	}()
+
+	close(ready)
+
+	if c.KVPrefix != "" {
+		return c.runKV(stop)
+	}
+	return c.runServiceCatalog(stop)
+}
+
+func (c *ConsulSource) runServiceCatalog(stop <-chan struct{}) error {
+	var lastIndex uint64
+	known := map[string]*route.Endpoint{}
+	backoff := consulErrorBackoffMin
+
+	for {
+		select {
+		case <-stop:
+			return nil
+		default:
+		}
+
+		services, meta, err := c.client.Health().Service(c.ServicePrefix, "", true, &consulapi.QueryOptions{
+			WaitIndex: lastIndex,
+		})
+		if err != nil {
+			c.setHealth(err)
+
+			select {
+			case <-stop:
+				return nil
+			case <-time.After(backoff):
+			}
+
+			backoff = nextConsulBackoff(backoff)
+			continue
+		}
+		c.setHealth(nil)
+		backoff = consulErrorBackoffMin
+
+		if meta.LastIndex == lastIndex {
+			continue
+		}
+		lastIndex = meta.LastIndex
+
+		seen := map[string]bool{}
+		for _, svc := range services {
+			uri := route.Uri(svc.Service.Service)
+			addr := fmt.Sprintf("%s:%d", svc.Service.Address, svc.Service.Port)
+			seen[addr] = true
+
+			if known[addr] != nil {
+				continue
+			}
+
+			endpoint := route.NewEndpoint(
+				svc.Service.Service,
+				svc.Service.Address,
+				uint16(svc.Service.Port),
+				svc.Service.ID,
+				strconv.Itoa(0),
+				svc.Service.Meta,
+				0,
+				"",
+				models.ModificationTag{},
+			)
+
+			known[addr] = endpoint
+			c.routeRegistry.Register(uri, endpoint)
+		}
+
+		for addr, endpoint := range known {
+			if !seen[addr] {
+				c.routeRegistry.Unregister(route.Uri(c.ServicePrefix), endpoint)
+				delete(known, addr)
+			}
+		}
+	}
+}
+
+// consulKVEntry is the expected shape of the JSON-encoded value under each
+// key beneath KVPrefix.
+type consulKVEntry struct {
+	Uri      route.Uri         `json:"uri"`
+	Host     string            `json:"host"`
+	Port     uint16            `json:"port"`
+	Tags     map[string]string `json:"tags"`
+	App      string            `json:"app"`
+	Instance string            `json:"instance"`
+}
+
+func (e *consulKVEntry) toEndpoint() *route.Endpoint {
+	return route.NewEndpoint(
+		e.App,
+		e.Host,
+		e.Port,
+		e.Instance,
+		"",
+		e.Tags,
+		0,
+		"",
+		models.ModificationTag{},
+	)
+}
+
+// runKV watches KVPrefix with blocking queries. On every change it
+// Registers every decodable key in the listing and diffs the listing
+// against the previous one to Unregister keys that disappeared, the KV
+// analogue of runServiceCatalog's catalog diffing.
+func (c *ConsulSource) runKV(stop <-chan struct{}) error {
+	var lastIndex uint64
+	known := map[string]etcdKnownEntry{}
+	backoff := consulErrorBackoffMin
+
+	for {
+		select {
+		case <-stop:
+			return nil
+		default:
+		}
+
+		pairs, meta, err := c.client.KV().List(c.KVPrefix, &consulapi.QueryOptions{
+			WaitIndex: lastIndex,
+		})
+		if err != nil {
+			c.setHealth(err)
+
+			select {
+			case <-stop:
+				return nil
+			case <-time.After(backoff):
+			}
+
+			backoff = nextConsulBackoff(backoff)
+			continue
+		}
+		c.setHealth(nil)
+		backoff = consulErrorBackoffMin
+
+		if meta.LastIndex == lastIndex {
+			continue
+		}
+		lastIndex = meta.LastIndex
+
+		seen := map[string]etcdKnownEntry{}
+		for _, pair := range pairs {
+			var entry consulKVEntry
+			if jsonErr := json.Unmarshal(pair.Value, &entry); jsonErr != nil {
+				continue
+			}
+
+			endpoint := entry.toEndpoint()
+			seen[pair.Key] = etcdKnownEntry{uri: entry.Uri, endpoint: endpoint}
+			c.routeRegistry.Register(entry.Uri, endpoint)
+		}
+
+		for _, removed := range removedKeys(known, seen) {
+			c.routeRegistry.Unregister(removed.uri, removed.endpoint)
+		}
+		known = seen
+	}
+}
+
+// nextConsulBackoff doubles current, capped at consulErrorBackoffMax, for
+// the delay before retrying a failing blocking query.
+func nextConsulBackoff(current time.Duration) time.Duration {
+	next := current * 2
+	if next > consulErrorBackoffMax {
+		return consulErrorBackoffMax
+	}
+	return next
+}
+
+// Health reports the error from the most recent blocking query, if any.
+func (c *ConsulSource) Health() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.healthErr
+}
+
+func (c *ConsulSource) setHealth(err error) {
+	c.mu.Lock()
+	c.healthErr = err
+	c.mu.Unlock()
+}
@@ -0,0 +1,37 @@
+package source
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestNextConsulBackoffDoublesAndCaps(t *testing.T) {
+	backoff := consulErrorBackoffMin
+
+	for i := 0; i < 20; i++ {
+		backoff = nextConsulBackoff(backoff)
+	}
+
+	if backoff != consulErrorBackoffMax {
+		t.Fatalf("expected repeated failures to cap the backoff at %v, got %v", consulErrorBackoffMax, backoff)
+	}
+
+	if got := nextConsulBackoff(time.Second); got != 2*time.Second {
+		t.Fatalf("expected a single doubling from 1s to produce 2s, got %v", got)
+	}
+}
+
+func TestConsulKVEntryUnmarshalsAndBuildsEndpoint(t *testing.T) {
+	data := []byte(`{"uri":"foo.example.com","host":"10.0.0.1","port":8080,"app":"app-1","instance":"inst-1"}`)
+
+	var entry consulKVEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	endpoint := entry.toEndpoint()
+	if endpoint.Host != "10.0.0.1" || endpoint.Port != 8080 || endpoint.PrivateInstanceId != "inst-1" {
+		t.Fatalf("unexpected endpoint built from KV entry: %+v", endpoint)
+	}
+}
@@ -0,0 +1,203 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"code.cloudfoundry.org/routing-api/models"
+	clientv3 "github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/mvcc/mvccpb"
+
+	"github.com/df010/router-lite/registry"
+	"github.com/df010/router-lite/route"
+)
+
+// EtcdSource watches a `/routes/` prefix in etcd v3 and keeps a
+// registry.Registry in sync. Each key's value is expected to be a
+// JSON-encoded etcdRouteEntry; each put/delete maps
+// directly to Register/Unregister.
+type EtcdSource struct {
+	client        *clientv3.Client
+	routeRegistry registry.Registry
+	prefix        string
+
+	// known tracks the uri/endpoint last registered for each etcd key, so
+	// resync can diff against it and Unregister keys that disappeared
+	// while this source wasn't watching (startup, and after a compaction
+	// forces a resync). Only ever touched from the Run goroutine.
+	known map[string]etcdKnownEntry
+
+	mu        sync.Mutex
+	healthErr error
+}
+
+type etcdKnownEntry struct {
+	uri      route.Uri
+	endpoint *route.Endpoint
+}
+
+// NewEtcdSource returns an EtcdSource that watches keys under prefix
+// (typically "/routes/").
+func NewEtcdSource(client *clientv3.Client, routeRegistry registry.Registry, prefix string) *EtcdSource {
+	return &EtcdSource{
+		client:        client,
+		routeRegistry: routeRegistry,
+		prefix:        prefix,
+		known:         map[string]etcdKnownEntry{},
+	}
+}
+
+type etcdRouteEntry struct {
+	Uri      route.Uri         `json:"uri"`
+	Host     string            `json:"host"`
+	Port     uint16            `json:"port"`
+	Tags     map[string]string `json:"tags"`
+	App      string            `json:"app"`
+	Instance string            `json:"instance"`
+}
+
+// Run performs an initial resync against the current revision, then watches
+// the prefix for incremental changes, resyncing whenever etcd reports the
+// watch was compacted out from under it.
+func (e *EtcdSource) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-signals
+		cancel()
+	}()
+
+	rev, err := e.resync(ctx)
+	if err != nil {
+		e.setHealth(err)
+		return err
+	}
+
+	close(ready)
+
+	for {
+		watchCh := e.client.Watch(ctx, e.prefix, clientv3.WithPrefix(), clientv3.WithRev(rev+1), clientv3.WithPrevKV())
+		for resp := range watchCh {
+			if resp.Canceled {
+				if ctx.Err() != nil {
+					return nil
+				}
+				break
+			}
+
+			if resp.CompactRevision != 0 {
+				// our watch revision was compacted away; fall back to a
+				// full resync from the latest revision.
+				break
+			}
+
+			e.setHealth(resp.Err())
+			for _, ev := range resp.Events {
+				e.apply(ev)
+			}
+			rev = resp.Header.Revision
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		rev, err = e.resync(ctx)
+		if err != nil {
+			e.setHealth(err)
+			return err
+		}
+	}
+}
+
+func (e *EtcdSource) resync(ctx context.Context) (int64, error) {
+	resp, err := e.client.Get(ctx, e.prefix, clientv3.WithPrefix())
+	if err != nil {
+		return 0, err
+	}
+
+	seen := map[string]etcdKnownEntry{}
+	for _, kv := range resp.Kvs {
+		var entry etcdRouteEntry
+		if jsonErr := json.Unmarshal(kv.Value, &entry); jsonErr != nil {
+			continue
+		}
+		endpoint := entry.toEndpoint()
+		seen[string(kv.Key)] = etcdKnownEntry{uri: entry.Uri, endpoint: endpoint}
+		e.routeRegistry.Register(entry.Uri, endpoint)
+	}
+
+	// Unregister any key we knew about before this resync that's no
+	// longer present, so routes deleted while we weren't watching (or
+	// whose delete event we never got because of a compaction) don't
+	// linger until the unrelated stale-pruning timer catches up.
+	for _, entry := range removedKeys(e.known, seen) {
+		e.routeRegistry.Unregister(entry.uri, entry.endpoint)
+	}
+	e.known = seen
+
+	e.setHealth(nil)
+	return resp.Header.Revision, nil
+}
+
+// removedKeys returns the known entries whose key is absent from seen,
+// i.e. the routes a resync needs to Unregister.
+func removedKeys(known, seen map[string]etcdKnownEntry) []etcdKnownEntry {
+	var removed []etcdKnownEntry
+	for key, entry := range known {
+		if _, ok := seen[key]; !ok {
+			removed = append(removed, entry)
+		}
+	}
+	return removed
+}
+
+func (e *EtcdSource) apply(ev *clientv3.Event) {
+	key := string(ev.Kv.Key)
+
+	if ev.Type == mvccpb.DELETE {
+		var entry etcdRouteEntry
+		if jsonErr := json.Unmarshal(ev.PrevKv.GetValue(), &entry); jsonErr != nil {
+			return
+		}
+		e.routeRegistry.Unregister(entry.Uri, entry.toEndpoint())
+		delete(e.known, key)
+		return
+	}
+
+	var entry etcdRouteEntry
+	if jsonErr := json.Unmarshal(ev.Kv.Value, &entry); jsonErr != nil {
+		return
+	}
+	endpoint := entry.toEndpoint()
+	e.routeRegistry.Register(entry.Uri, endpoint)
+	e.known[key] = etcdKnownEntry{uri: entry.Uri, endpoint: endpoint}
+}
+
+func (entry *etcdRouteEntry) toEndpoint() *route.Endpoint {
+	return route.NewEndpoint(
+		entry.App,
+		entry.Host,
+		entry.Port,
+		entry.Instance,
+		"",
+		entry.Tags,
+		0,
+		"",
+		models.ModificationTag{},
+	)
+}
+
+// Health reports the error from the most recent watch or resync, if any.
+func (e *EtcdSource) Health() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.healthErr
+}
+
+func (e *EtcdSource) setHealth(err error) {
+	e.mu.Lock()
+	e.healthErr = err
+	e.mu.Unlock()
+}
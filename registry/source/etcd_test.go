@@ -0,0 +1,26 @@
+package source
+
+import (
+	"testing"
+
+	"github.com/df010/router-lite/route"
+)
+
+func TestRemovedKeysDiffsKnownAgainstSeen(t *testing.T) {
+	stillThere := etcdKnownEntry{uri: route.Uri("foo.example.com"), endpoint: &route.Endpoint{}}
+	goneNow := etcdKnownEntry{uri: route.Uri("bar.example.com"), endpoint: &route.Endpoint{}}
+
+	known := map[string]etcdKnownEntry{
+		"/routes/foo": stillThere,
+		"/routes/bar": goneNow,
+	}
+	seen := map[string]etcdKnownEntry{
+		"/routes/foo": stillThere,
+	}
+
+	removed := removedKeys(known, seen)
+
+	if len(removed) != 1 || removed[0].uri != goneNow.uri {
+		t.Fatalf("expected only the vanished key to be reported removed, got %+v", removed)
+	}
+}
@@ -0,0 +1,130 @@
+package source
+
+import (
+	"os"
+	"sync"
+
+	"code.cloudfoundry.org/routing-api"
+	"code.cloudfoundry.org/routing-api/models"
+
+	"github.com/df010/router-lite/registry"
+	"github.com/df010/router-lite/route"
+)
+
+// RoutingAPISource streams route events from Cloud Foundry's routing-api
+// over its HTTP+SSE event stream and mirrors them into a registry.Registry.
+type RoutingAPISource struct {
+	client        routing_api.Client
+	routeRegistry registry.Registry
+
+	mu        sync.Mutex
+	healthErr error
+}
+
+// NewRoutingAPISource returns a RoutingAPISource backed by the given
+// routing-api client.
+func NewRoutingAPISource(client routing_api.Client, routeRegistry registry.Registry) *RoutingAPISource {
+	return &RoutingAPISource{
+		client:        client,
+		routeRegistry: routeRegistry,
+	}
+}
+
+// Run resyncs the full route table, then streams incremental events until
+// signals fires. An error reading the event stream ends Run with that
+// error; the stream is not reconnected.
+func (s *RoutingAPISource) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	if err := s.resync(); err != nil {
+		s.setHealth(err)
+		return err
+	}
+
+	eventSource, err := s.client.SubscribeToEvents()
+	if err != nil {
+		s.setHealth(err)
+		return err
+	}
+	defer eventSource.Close()
+
+	close(ready)
+
+	events := make(chan routing_api.Event)
+	errs := make(chan error, 1)
+	go func() {
+		for {
+			event, err := eventSource.Next()
+			if err != nil {
+				errs <- err
+				return
+			}
+			events <- event
+		}
+	}()
+
+	for {
+		select {
+		case <-signals:
+			return nil
+		case err := <-errs:
+			s.setHealth(err)
+			return err
+		case event := <-events:
+			s.setHealth(nil)
+			s.apply(event)
+		}
+	}
+}
+
+func (s *RoutingAPISource) resync() error {
+	routes, err := s.client.Routes()
+	if err != nil {
+		return err
+	}
+
+	for _, r := range routes {
+		s.routeRegistry.Register(route.Uri(r.Route), toEndpoint(r))
+	}
+
+	s.setHealth(nil)
+	return nil
+}
+
+func (s *RoutingAPISource) apply(event routing_api.Event) {
+	endpoint := toEndpoint(event.Route)
+	uri := route.Uri(event.Route.Route)
+
+	switch event.Action {
+	case "Upsert":
+		s.routeRegistry.Register(uri, endpoint)
+	case "Delete":
+		s.routeRegistry.Unregister(uri, endpoint)
+	}
+}
+
+// Health reports the error from the most recent resync or stream read, if
+// any.
+func (s *RoutingAPISource) Health() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.healthErr
+}
+
+func (s *RoutingAPISource) setHealth(err error) {
+	s.mu.Lock()
+	s.healthErr = err
+	s.mu.Unlock()
+}
+
+func toEndpoint(r models.Route) *route.Endpoint {
+	return route.NewEndpoint(
+		"",
+		r.IP,
+		uint16(r.Port),
+		"",
+		"",
+		nil,
+		0,
+		r.RouteServiceUrl,
+		r.ModificationTag,
+	)
+}
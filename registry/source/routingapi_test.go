@@ -0,0 +1,24 @@
+package source
+
+import (
+	"testing"
+
+	"code.cloudfoundry.org/routing-api/models"
+)
+
+func TestToEndpointDoesNotReuseRouteServiceUrlAsInstanceId(t *testing.T) {
+	r := models.Route{
+		IP:              "10.0.0.1",
+		Port:            8080,
+		RouteServiceUrl: "https://route-service.example.com",
+	}
+
+	endpoint := toEndpoint(r)
+
+	if endpoint.PrivateInstanceId == r.RouteServiceUrl {
+		t.Fatalf("expected PrivateInstanceId not to be the route service URL, got %q", endpoint.PrivateInstanceId)
+	}
+	if endpoint.RouteServiceUrl != r.RouteServiceUrl {
+		t.Fatalf("expected RouteServiceUrl to be threaded through, got %q", endpoint.RouteServiceUrl)
+	}
+}
@@ -0,0 +1,106 @@
+// Package source implements pluggable service-discovery backends that feed
+// a registry.Registry, mirroring the Register/Unregister calls that
+// mbus.Subscriber makes from NATS messages.
+package source
+
+import (
+	"os"
+)
+
+// Source is a control-plane feed into the route registry. Implementations
+// translate their native event stream (NATS, Consul blocking queries, etcd
+// watches, routing-api SSE, ...) into Register/Unregister calls against a
+// registry.Registry.
+type Source interface {
+	// Run subscribes to the backend and blocks until signals is closed or
+	// receives a value, mirroring the ifrit-style process convention used
+	// by mbus.Subscriber.
+	Run(signals <-chan os.Signal, ready chan<- struct{}) error
+
+	// Health reports whether the source's connection to its backend is
+	// currently healthy. A non-nil error surfaces the last failure.
+	Health() error
+}
+
+// MultiSource fans multiple Sources into a single ifrit-compatible process
+// so a router can be fed by several control planes at once.
+type MultiSource struct {
+	sources []Source
+}
+
+// NewMultiSource returns a MultiSource that runs every given Source
+// concurrently.
+func NewMultiSource(sources ...Source) *MultiSource {
+	return &MultiSource{sources: sources}
+}
+
+// Run starts every underlying Source in its own goroutine, signals ready
+// once all of them have signaled ready, and stops them all when signals
+// fires or any one of them exits with an error.
+func (m *MultiSource) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	errs := make(chan error, len(m.sources))
+	readys := make(chan struct{}, len(m.sources))
+	stops := make([]chan os.Signal, len(m.sources))
+
+	for i, s := range m.sources {
+		stops[i] = make(chan os.Signal, 1)
+		go func(s Source, stop chan os.Signal) {
+			r := make(chan struct{})
+			done := make(chan error, 1)
+			go func() { done <- s.Run(stop, r) }()
+
+			select {
+			case <-r:
+				readys <- struct{}{}
+				errs <- <-done
+			case err := <-done:
+				errs <- err
+			}
+		}(s, stops[i])
+	}
+
+	readyCount := 0
+	for readyCount < len(m.sources) {
+		select {
+		case <-readys:
+			readyCount++
+		case err := <-errs:
+			// A source exited before ever becoming ready (e.g. it couldn't
+			// reach its backend on the initial connect). Waiting for the
+			// rest to become ready would block forever, so stop everything
+			// and surface the failure instead.
+			for _, stop := range stops {
+				close(stop)
+			}
+			return err
+		}
+	}
+	close(ready)
+
+	select {
+	case sig := <-signals:
+		for _, stop := range stops {
+			stop <- sig
+		}
+		for i := 0; i < len(m.sources); i++ {
+			<-errs
+		}
+		return nil
+	case err := <-errs:
+		for _, stop := range stops {
+			close(stop)
+		}
+		return err
+	}
+}
+
+// Health returns the first unhealthy Source's error, or nil if all sources
+// are healthy.
+func (m *MultiSource) Health() error {
+	for _, s := range m.sources {
+		if err := s.Health(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
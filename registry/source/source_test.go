@@ -0,0 +1,46 @@
+package source
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+type fakeSource struct {
+	runErr error
+	ready  bool
+}
+
+func (f *fakeSource) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	if f.ready {
+		close(ready)
+		<-signals
+		return nil
+	}
+	return f.runErr
+}
+
+func (f *fakeSource) Health() error { return nil }
+
+func TestMultiSourceRunSurfacesEarlyErrorInsteadOfHanging(t *testing.T) {
+	m := NewMultiSource(
+		&fakeSource{ready: true},
+		&fakeSource{runErr: errors.New("boom")},
+	)
+
+	signals := make(chan os.Signal)
+	ready := make(chan struct{})
+
+	done := make(chan error, 1)
+	go func() { done <- m.Run(signals, ready) }()
+
+	select {
+	case err := <-done:
+		if err == nil || err.Error() != "boom" {
+			t.Fatalf("expected the early source's error to surface, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("MultiSource.Run hung instead of surfacing the early source error")
+	}
+}
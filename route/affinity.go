@@ -0,0 +1,71 @@
+package route
+
+import "math"
+
+// AffinityPref biases endpoint selection toward endpoints whose Attributes
+// match Key/Value, analogous to spread/affinity placement preferences in
+// scheduler systems (e.g. "prefer same az", "prefer same rack").
+type AffinityPref struct {
+	Key    string
+	Value  string
+	Weight int
+}
+
+// affinityScore sums the weights of every AffinityPref that e.Attributes
+// satisfies.
+func affinityScore(e *Endpoint, prefs []AffinityPref) int {
+	score := 0
+	for _, pref := range prefs {
+		if e.Attributes != nil && e.Attributes[pref.Key] == pref.Value {
+			score += pref.Weight
+		}
+	}
+	return score
+}
+
+// WeightedSample returns a new Pool holding the same endpoints as p, but
+// reordered by a weighted-random draw derived from each endpoint's static
+// Weight plus its summed affinity score against prefs. It uses the
+// Efraimidis-Spirakis scheme (key = rand()^(1/weight), sorted descending)
+// so higher-weighted endpoints are merely more likely to sort first, not
+// guaranteed to.
+func (p *Pool) WeightedSample(prefs []AffinityPref) *Pool {
+	p.Lock()
+	entries := make([]*poolEntry, len(p.endpoints))
+	for i, e := range p.endpoints {
+		entries[i] = &poolEntry{endpoint: e.endpoint, updated: e.updated}
+	}
+	p.Unlock()
+
+	type keyed struct {
+		entry *poolEntry
+		key   float64
+	}
+
+	scored := make([]keyed, len(entries))
+	for i, e := range entries {
+		weight := e.endpoint.Weight
+		if weight < 1 {
+			weight = 1
+		}
+		weight += affinityScore(e.endpoint, prefs)
+		if weight < 1 {
+			weight = 1
+		}
+		scored[i] = keyed{entry: e, key: math.Pow(randFloat(), 1/float64(weight))}
+	}
+
+	for i := 1; i < len(scored); i++ {
+		for j := i; j > 0 && scored[j].key > scored[j-1].key; j-- {
+			scored[j], scored[j-1] = scored[j-1], scored[j]
+		}
+	}
+
+	ordered := &Pool{contextPath: p.contextPath, staleThreshold: p.staleThreshold}
+	ordered.endpoints = make([]*poolEntry, len(scored))
+	for i, s := range scored {
+		ordered.endpoints[i] = s.entry
+	}
+
+	return ordered
+}
@@ -0,0 +1,54 @@
+package route
+
+import (
+	"testing"
+	"time"
+
+	"code.cloudfoundry.org/routing-api/models"
+)
+
+func TestAffinityScoreSumsMatchingPrefs(t *testing.T) {
+	e := &Endpoint{Attributes: map[string]string{"az": "z1", "region": "us"}}
+	prefs := []AffinityPref{
+		{Key: "az", Value: "z1", Weight: 10},
+		{Key: "region", Value: "us", Weight: 5},
+		{Key: "az", Value: "z2", Weight: 100},
+	}
+
+	if score := affinityScore(e, prefs); score != 15 {
+		t.Fatalf("expected score 15, got %d", score)
+	}
+}
+
+func TestWeightedSampleOrdersAllEndpointsAndDoesNotAliasPool(t *testing.T) {
+	pool := NewPool(0, "")
+	for i := 0; i < 5; i++ {
+		pool.Put(&Endpoint{PrivateInstanceId: string(rune('a' + i)), Host: "10.0.0.1", Port: uint16(i)})
+	}
+
+	sampled := pool.WeightedSample(nil)
+
+	if len(sampled.endpoints) != 5 {
+		t.Fatalf("expected all 5 endpoints in the sample, got %d", len(sampled.endpoints))
+	}
+
+	for i, e := range sampled.endpoints {
+		if e == pool.endpoints[i] {
+			t.Fatalf("expected WeightedSample to copy poolEntry values, found a shared *poolEntry at index %d", i)
+		}
+	}
+
+	// Mutating the live pool's entry must not be visible through the
+	// already-returned sample, since they no longer share poolEntry structs.
+	now := time.Now()
+	pool.Lock()
+	pool.endpoints[0].updated = now
+	pool.endpoints[0].endpoint = &Endpoint{ModificationTag: models.ModificationTag{}}
+	pool.Unlock()
+
+	for _, e := range sampled.endpoints {
+		if e.endpoint == pool.endpoints[0].endpoint {
+			t.Fatalf("sampled pool entry aliases the live pool's endpoint pointer after mutation")
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package route
+
+import (
+	"fmt"
+	"time"
+
+	"code.cloudfoundry.org/routing-api/models"
+)
+
+// Endpoint represents a single backend instance that a Uri can route to.
+type Endpoint struct {
+	ApplicationId        string
+	Host                 string
+	Port                 uint16
+	PrivateInstanceId    string
+	PrivateInstanceIndex string
+	Tags                 map[string]string
+	RouteServiceUrl      string
+	ModificationTag      models.ModificationTag
+
+	// Weight biases this endpoint's share of traffic within its pool; a
+	// weight of 0 is treated as the default weight of 1.
+	Weight int
+	// Attributes carries arbitrary placement metadata (e.g. "az", "rack")
+	// that AffinityPref can match against in LookupWithAffinity.
+	Attributes map[string]string
+
+	// TLS reports whether this endpoint can be dialed directly over
+	// TLS/mTLS on PrivateInstancePort, using ServerCertDomainSAN to verify
+	// its certificate.
+	TLS                 bool
+	ServerCertDomainSAN string
+	PrivateInstancePort uint16
+
+	staleThreshold time.Duration
+}
+
+// NewEndpoint returns a new Endpoint. staleThresholdInSeconds of 0 means the
+// endpoint never goes stale on its own and is only pruned when its pool is.
+func NewEndpoint(
+	appId string,
+	host string,
+	port uint16,
+	privateInstanceId string,
+	privateInstanceIndex string,
+	tags map[string]string,
+	staleThresholdInSeconds int,
+	routeServiceUrl string,
+	modificationTag models.ModificationTag,
+) *Endpoint {
+	return &Endpoint{
+		ApplicationId:        appId,
+		Host:                 host,
+		Port:                 port,
+		PrivateInstanceId:    privateInstanceId,
+		PrivateInstanceIndex: privateInstanceIndex,
+		Tags:                 tags,
+		RouteServiceUrl:      routeServiceUrl,
+		ModificationTag:      modificationTag,
+		Weight:               1,
+		staleThreshold:       time.Duration(staleThresholdInSeconds) * time.Second,
+	}
+}
+
+// CanonicalAddr returns the host:port this Endpoint dials.
+func (e *Endpoint) CanonicalAddr() string {
+	return fmt.Sprintf("%s:%d", e.Host, e.Port)
+}
+
+// Equal compares two endpoints by their dial address and instance id, the
+// same identity Pool.Put and Pool.Remove key off of.
+func (e *Endpoint) Equal(other *Endpoint) bool {
+	if other == nil {
+		return false
+	}
+	return e.PrivateInstanceId == other.PrivateInstanceId && e.CanonicalAddr() == other.CanonicalAddr()
+}
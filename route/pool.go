@@ -0,0 +1,167 @@
+package route
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Pool is the set of Endpoints registered against a single Uri.
+type Pool struct {
+	sync.Mutex
+
+	contextPath    string
+	staleThreshold time.Duration
+
+	endpoints []*poolEntry
+}
+
+type poolEntry struct {
+	endpoint *Endpoint
+	updated  time.Time
+}
+
+// NewPool returns a new, empty Pool. staleThreshold is stored on the pool
+// and carried over by WeightedSample and FilterTLS; PruneEndpoints does not
+// read it and prunes against the threshold its caller passes in.
+func NewPool(staleThreshold time.Duration, contextPath string) *Pool {
+	return &Pool{
+		contextPath:    contextPath,
+		staleThreshold: staleThreshold,
+	}
+}
+
+// Put adds or updates an endpoint in the pool. accepted is false, and the
+// pool left untouched, when an existing endpoint's ModificationTag is not
+// succeeded by endpoint's (an out-of-order update); otherwise accepted is
+// true and added reports whether this was a brand-new endpoint (true) or
+// an update to one already present (false).
+func (p *Pool) Put(endpoint *Endpoint) (accepted bool, added bool) {
+	p.Lock()
+	defer p.Unlock()
+
+	now := time.Now()
+	for _, e := range p.endpoints {
+		if e.endpoint.Equal(endpoint) {
+			if !e.endpoint.ModificationTag.SucceededBy(&endpoint.ModificationTag) {
+				return false, false
+			}
+			e.endpoint = endpoint
+			e.updated = now
+			return true, false
+		}
+	}
+
+	p.endpoints = append(p.endpoints, &poolEntry{endpoint: endpoint, updated: now})
+	return true, true
+}
+
+// Remove removes an endpoint matching the given one, if present. Like Put,
+// accepted is false, and the pool left untouched, when the matching
+// endpoint's ModificationTag is not succeeded by endpoint's (a stale,
+// out-of-order Unregister arriving after a newer Register); removed
+// reports whether an endpoint was actually taken out of the pool.
+func (p *Pool) Remove(endpoint *Endpoint) (accepted bool, removed bool) {
+	p.Lock()
+	defer p.Unlock()
+
+	for i, e := range p.endpoints {
+		if e.endpoint.Equal(endpoint) {
+			if !e.endpoint.ModificationTag.SucceededBy(&endpoint.ModificationTag) {
+				return false, false
+			}
+			p.endpoints = append(p.endpoints[:i], p.endpoints[i+1:]...)
+			return true, true
+		}
+	}
+	return true, false
+}
+
+// IsEmpty reports whether the pool has no endpoints left.
+func (p *Pool) IsEmpty() bool {
+	p.Lock()
+	defer p.Unlock()
+	return len(p.endpoints) == 0
+}
+
+// Each calls f once per endpoint currently in the pool.
+func (p *Pool) Each(f func(endpoint *Endpoint)) {
+	p.Lock()
+	endpoints := make([]*Endpoint, len(p.endpoints))
+	for i, e := range p.endpoints {
+		endpoints[i] = e.endpoint
+	}
+	p.Unlock()
+
+	for _, e := range endpoints {
+		f(e)
+	}
+}
+
+// FilterTLS returns a new Pool holding only this pool's TLS-capable
+// endpoints, so a downstream proxy can dial them directly instead of
+// through a route service.
+func (p *Pool) FilterTLS() *Pool {
+	p.Lock()
+	defer p.Unlock()
+
+	filtered := &Pool{contextPath: p.contextPath, staleThreshold: p.staleThreshold}
+	for _, e := range p.endpoints {
+		if e.endpoint.TLS {
+			filtered.endpoints = append(filtered.endpoints, &poolEntry{endpoint: e.endpoint, updated: e.updated})
+		}
+	}
+
+	return filtered
+}
+
+// MarkUpdated bumps every endpoint's last-updated time to now, used to
+// avoid pruning a pool's entire contents right after reconnecting to a
+// source that had been disconnected.
+func (p *Pool) MarkUpdated(now time.Time) {
+	p.Lock()
+	defer p.Unlock()
+	for _, e := range p.endpoints {
+		e.updated = now
+	}
+}
+
+// PruneEndpoints removes and returns endpoints that haven't been updated
+// within staleThreshold.
+func (p *Pool) PruneEndpoints(staleThreshold time.Duration) []*Endpoint {
+	p.Lock()
+	defer p.Unlock()
+
+	now := time.Now()
+	var pruned []*Endpoint
+	var kept []*poolEntry
+
+	for _, e := range p.endpoints {
+		if now.Sub(e.updated) > staleThreshold {
+			pruned = append(pruned, e.endpoint)
+		} else {
+			kept = append(kept, e)
+		}
+	}
+
+	p.endpoints = kept
+	return pruned
+}
+
+// randomEndpoint picks an endpoint uniformly at random; callers needing a
+// weighted or affinity-biased pick use Pool.WeightedSample instead.
+func (p *Pool) randomEndpoint() *Endpoint {
+	p.Lock()
+	defer p.Unlock()
+
+	if len(p.endpoints) == 0 {
+		return nil
+	}
+	return p.endpoints[rand.Intn(len(p.endpoints))].endpoint
+}
+
+// randFloat returns a pseudo-random float64 in (0, 1], avoiding a draw of 0,
+// whose Efraimidis-Spirakis key in WeightedSample is 0 whatever the weight.
+func randFloat() float64 {
+	return 1 - rand.Float64()
+}
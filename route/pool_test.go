@@ -0,0 +1,76 @@
+package route
+
+import (
+	"testing"
+
+	"code.cloudfoundry.org/routing-api/models"
+)
+
+func TestPutRejectsStaleModificationTag(t *testing.T) {
+	pool := NewPool(0, "")
+
+	current := &Endpoint{PrivateInstanceId: "instance", Host: "10.0.0.1", Port: 8080, ModificationTag: models.ModificationTag{Guid: "a", Index: 2}}
+	if accepted, added := pool.Put(current); !accepted || !added {
+		t.Fatalf("expected the first Put to be accepted and added, got accepted=%v added=%v", accepted, added)
+	}
+
+	stale := &Endpoint{PrivateInstanceId: "instance", Host: "10.0.0.1", Port: 8080, ModificationTag: models.ModificationTag{Guid: "a", Index: 1}}
+	accepted, added := pool.Put(stale)
+	if accepted || added {
+		t.Fatalf("expected a stale Put to be rejected, got accepted=%v added=%v", accepted, added)
+	}
+
+	if pool.endpoints[0].endpoint != current {
+		t.Fatalf("expected the pool to still hold the original endpoint after a rejected stale Put")
+	}
+}
+
+func TestRemoveRejectsStaleModificationTag(t *testing.T) {
+	pool := NewPool(0, "")
+
+	current := &Endpoint{PrivateInstanceId: "instance", Host: "10.0.0.1", Port: 8080, ModificationTag: models.ModificationTag{Guid: "a", Index: 2}}
+	pool.Put(current)
+
+	stale := &Endpoint{PrivateInstanceId: "instance", Host: "10.0.0.1", Port: 8080, ModificationTag: models.ModificationTag{Guid: "a", Index: 1}}
+	accepted, removed := pool.Remove(stale)
+	if accepted || removed {
+		t.Fatalf("expected a stale Remove to be rejected, got accepted=%v removed=%v", accepted, removed)
+	}
+	if pool.IsEmpty() {
+		t.Fatalf("expected the endpoint to remain in the pool after a rejected stale Remove")
+	}
+
+	newer := &Endpoint{PrivateInstanceId: "instance", Host: "10.0.0.1", Port: 8080, ModificationTag: models.ModificationTag{Guid: "a", Index: 3}}
+	accepted, removed = pool.Remove(newer)
+	if !accepted || !removed {
+		t.Fatalf("expected a Remove with a succeeding tag to be accepted, got accepted=%v removed=%v", accepted, removed)
+	}
+	if !pool.IsEmpty() {
+		t.Fatalf("expected the pool to be empty after the accepted Remove")
+	}
+}
+
+func TestFilterTLSDoesNotAliasSourcePool(t *testing.T) {
+	pool := NewPool(0, "")
+	pool.Put(&Endpoint{PrivateInstanceId: "tls-1", Host: "10.0.0.1", Port: 1, TLS: true, ServerCertDomainSAN: "a.example.com"})
+	pool.Put(&Endpoint{PrivateInstanceId: "plain-1", Host: "10.0.0.2", Port: 2})
+
+	filtered := pool.FilterTLS()
+
+	if len(filtered.endpoints) != 1 {
+		t.Fatalf("expected 1 TLS endpoint, got %d", len(filtered.endpoints))
+	}
+	if filtered.endpoints[0] == pool.endpoints[0] {
+		t.Fatalf("expected FilterTLS to copy the poolEntry, found a shared *poolEntry")
+	}
+
+	// Mutating the source pool's entry (as a live Register/prune would)
+	// must not be visible through the already-returned filtered pool.
+	pool.Lock()
+	pool.endpoints[0].endpoint = &Endpoint{TLS: true, ServerCertDomainSAN: "mutated.example.com"}
+	pool.Unlock()
+
+	if filtered.endpoints[0].endpoint.ServerCertDomainSAN == "mutated.example.com" {
+		t.Fatalf("filtered pool entry aliases the live pool's endpoint pointer after mutation")
+	}
+}
@@ -0,0 +1,39 @@
+package route
+
+import (
+	"errors"
+	"strings"
+)
+
+// Uri is a route host/path, e.g. "foo.example.com/bar".
+type Uri string
+
+// RouteKey normalizes a Uri for use as a trie key: lower-cased, with any
+// trailing slash and query string stripped.
+func (u Uri) RouteKey() Uri {
+	var uri string
+	if idx := strings.Index(string(u), "?"); idx >= 0 {
+		uri = string(u)[:idx]
+	} else {
+		uri = string(u)
+	}
+
+	return Uri(strings.ToLower(strings.TrimSuffix(uri, "/")))
+}
+
+// NextWildcard strips the left-most path segment off the host portion of
+// the Uri, returning a broader wildcard candidate for Lookup to retry
+// against. It returns an error once there are no more segments to strip.
+func (u Uri) NextWildcard() (Uri, error) {
+	s := string(u)
+	idx := strings.Index(s, ".")
+	if idx < 0 {
+		return u, errors.New("no more wildcard candidates")
+	}
+
+	return Uri(s[idx+1:]), nil
+}
+
+func (u Uri) String() string {
+	return string(u)
+}